@@ -0,0 +1,55 @@
+package config
+
+import "time"
+
+// MempoolConfig defines the configuration options for the Tendermint
+// mempool.
+//
+// This only carries the fields internal/mempool actually references; the
+// rest of MempoolConfig, and the rest of this package, lives upstream and
+// isn't part of this series.
+type MempoolConfig struct {
+	// Broadcast, when true, gossips validated txs to connected peers. When
+	// false, the reactor accepts txs via CheckTx but never forwards them.
+	Broadcast bool `mapstructure:"broadcast"`
+
+	// MaxTxBytes is the largest size, in bytes, a single tx may be.
+	MaxTxBytes int `mapstructure:"max_tx_bytes"`
+
+	// MaxBatchBytes and MaxBatchTxs bound how many bytes, and how many txs,
+	// a single outbound gossip envelope to a peer may contain.
+	MaxBatchBytes int `mapstructure:"max_batch_bytes"`
+	MaxBatchTxs   int `mapstructure:"max_batch_txs"`
+
+	// BatchFlushInterval bounds how long a partially-filled outbound batch
+	// may sit before being sent anyway. Zero uses a small built-in default.
+	BatchFlushInterval time.Duration `mapstructure:"batch_flush_interval"`
+
+	// GossipMode selects how txs are relayed to peers: "push" (send the
+	// full tx body), "announce" (send a hash, let the peer pull the body
+	// via TxRequest), or "hybrid" (behaves like announce).
+	GossipMode string `mapstructure:"gossip_mode"`
+
+	// GossipOrder selects the order txs are offered to peers in: "fifo"
+	// (the default, left as "") or "priority".
+	GossipOrder string `mapstructure:"gossip_order"`
+
+	// MaxPeerBytesPerSecond, MaxPeerTxsPerSecond, and
+	// MaxGlobalInboundBytesPerSecond bound inbound tx traffic accepted from
+	// a single peer, and across all peers combined, respectively. Zero
+	// means unlimited.
+	MaxPeerBytesPerSecond          int64 `mapstructure:"max_peer_bytes_per_second"`
+	MaxPeerTxsPerSecond            int64 `mapstructure:"max_peer_txs_per_second"`
+	MaxGlobalInboundBytesPerSecond int64 `mapstructure:"max_global_inbound_bytes_per_second"`
+}
+
+// DefaultMempoolConfig returns the default configuration for the mempool.
+func DefaultMempoolConfig() *MempoolConfig {
+	return &MempoolConfig{
+		Broadcast:     true,
+		MaxTxBytes:    1024 * 1024,
+		MaxBatchBytes: 4 * 1024 * 1024,
+		MaxBatchTxs:   256,
+		GossipMode:    "push",
+	}
+}
@@ -0,0 +1,134 @@
+package mempool
+
+import (
+	"context"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/libs/log"
+	protomem "github.com/tendermint/tendermint/proto/tendermint/mempool"
+	"github.com/tendermint/tendermint/types"
+)
+
+// gossipSender abstracts the outbound p2p send that flushing a txBatcher
+// performs, so the batching logic below can be driven by a fake in tests
+// without needing a real p2p.Channel.
+type gossipSender interface {
+	// SendTxs pushes the full bodies of txs to peerID.
+	SendTxs(ctx context.Context, peerID types.NodeID, txs [][]byte)
+
+	// SendAnnounce announces hashes to peerID, expecting it to pull any it
+	// doesn't already have via a TxRequest.
+	SendAnnounce(ctx context.Context, peerID types.NodeID, hashes [][]byte)
+}
+
+// channelGossipSender is the production gossipSender, sending over a real
+// p2p.Channel.
+type channelGossipSender struct {
+	ch *p2p.Channel
+}
+
+func (s channelGossipSender) SendTxs(ctx context.Context, peerID types.NodeID, txs [][]byte) {
+	select {
+	case s.ch.Out <- p2p.Envelope{To: peerID, Message: &protomem.Txs{Txs: txs}}:
+	case <-ctx.Done():
+	}
+}
+
+func (s channelGossipSender) SendAnnounce(ctx context.Context, peerID types.NodeID, hashes [][]byte) {
+	select {
+	case s.ch.Out <- p2p.Envelope{To: peerID, Message: &protomem.TxAnnounce{Hashes: hashes}}:
+	case <-ctx.Done():
+	}
+}
+
+// txBatcher accumulates txs (or, in announce mode, just their hashes)
+// destined for a single peer into a size-bounded batch, flushing eagerly
+// once MaxBatchTxs or MaxBatchBytes would otherwise be exceeded. It is the
+// shared core of broadcastTxRoutineFIFO and broadcastTxRoutinePriority,
+// which differ only in how they pick the next candidate tx; callers are
+// responsible for flushing it on their own flush-interval timer too, so a
+// partial batch doesn't sit indefinitely during a quiet period.
+type txBatcher struct {
+	peerID   types.NodeID
+	maxTxs   int
+	maxBytes int
+	sender   gossipSender
+	metrics  *Metrics
+	logger   log.Logger
+
+	batch      [][]byte
+	batchBytes int
+	hashBatch  [][]byte
+}
+
+func newTxBatcher(peerID types.NodeID, maxTxs, maxBytes int, sender gossipSender, metrics *Metrics, logger log.Logger) *txBatcher {
+	return &txBatcher{
+		peerID:    peerID,
+		maxTxs:    maxTxs,
+		maxBytes:  maxBytes,
+		sender:    sender,
+		metrics:   metrics,
+		logger:    logger,
+		batch:     make([][]byte, 0, maxTxs),
+		hashBatch: make([][]byte, 0, maxTxs),
+	}
+}
+
+// QueueHash adds hash to the announce batch, flushing eagerly if that
+// fills it.
+func (b *txBatcher) QueueHash(ctx context.Context, hash []byte) {
+	b.hashBatch = append(b.hashBatch, hash)
+
+	if len(b.hashBatch) >= b.maxTxs {
+		b.Flush(ctx)
+	}
+}
+
+// QueueTx adds tx to the push batch, flushing first if tx would overflow
+// MaxBatchTxs/MaxBatchBytes, and again afterward if it now exactly fills
+// either bound.
+func (b *txBatcher) QueueTx(ctx context.Context, tx []byte) {
+	txBytes := len(tx)
+	if len(b.batch) > 0 && (len(b.batch) >= b.maxTxs || b.batchBytes+txBytes > b.maxBytes) {
+		b.Flush(ctx)
+	}
+
+	b.batch = append(b.batch, tx)
+	b.batchBytes += txBytes
+
+	if len(b.batch) >= b.maxTxs || b.batchBytes >= b.maxBytes {
+		b.Flush(ctx)
+	}
+}
+
+// Flush sends whatever is currently accumulated in the hash batch and/or
+// the tx batch, if anything, and resets both. It does not touch any flush
+// timer; that's the caller's responsibility.
+func (b *txBatcher) Flush(ctx context.Context) {
+	if len(b.hashBatch) > 0 {
+		b.sender.SendAnnounce(ctx, b.peerID, b.hashBatch)
+
+		if b.metrics != nil {
+			b.metrics.BatchSize.Observe(float64(len(b.hashBatch)))
+		}
+		if b.logger != nil {
+			b.logger.Debug("announced tx hashes to peer", "num_hashes", len(b.hashBatch), "peer", b.peerID)
+		}
+
+		b.hashBatch = make([][]byte, 0, b.maxTxs)
+	}
+
+	if len(b.batch) > 0 {
+		b.sender.SendTxs(ctx, b.peerID, b.batch)
+
+		if b.metrics != nil {
+			b.metrics.BatchSize.Observe(float64(len(b.batch)))
+		}
+		if b.logger != nil {
+			b.logger.Debug("gossiped tx batch to peer", "num_txs", len(b.batch), "peer", b.peerID)
+		}
+
+		b.batch = make([][]byte, 0, b.maxTxs)
+		b.batchBytes = 0
+	}
+}
@@ -0,0 +1,139 @@
+package mempool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// fakeGossipSender records every SendTxs/SendAnnounce call it receives, so
+// tests can assert on txBatcher's flushing behavior without a real
+// p2p.Channel.
+type fakeGossipSender struct {
+	txs       [][][]byte
+	announces [][][]byte
+}
+
+func (s *fakeGossipSender) SendTxs(_ context.Context, _ types.NodeID, txs [][]byte) {
+	s.txs = append(s.txs, txs)
+}
+
+func (s *fakeGossipSender) SendAnnounce(_ context.Context, _ types.NodeID, hashes [][]byte) {
+	s.announces = append(s.announces, hashes)
+}
+
+func TestTxBatcherFlushesOnMaxTxs(t *testing.T) {
+	sender := &fakeGossipSender{}
+	b := newTxBatcher(types.NodeID("peer1"), 2, 1<<20, sender, nil, nil)
+	ctx := context.Background()
+
+	b.QueueTx(ctx, []byte("a"))
+	require.Empty(t, sender.txs)
+
+	b.QueueTx(ctx, []byte("b"))
+	require.Equal(t, [][]byte{[]byte("a"), []byte("b")}, sender.txs[0])
+}
+
+func TestTxBatcherFlushesOnMaxBytes(t *testing.T) {
+	sender := &fakeGossipSender{}
+	b := newTxBatcher(types.NodeID("peer1"), 100, 3, sender, nil, nil)
+	ctx := context.Background()
+
+	b.QueueTx(ctx, []byte("ab"))
+	require.Empty(t, sender.txs)
+
+	// "ab" (2 bytes) + "cd" (2 bytes) would overflow the 3-byte ceiling, so
+	// the first tx must be flushed before "cd" is queued on its own.
+	b.QueueTx(ctx, []byte("cd"))
+	require.Equal(t, [][]byte{[]byte("ab")}, sender.txs[0])
+}
+
+func TestTxBatcherExplicitFlushSendsPartialBatch(t *testing.T) {
+	sender := &fakeGossipSender{}
+	b := newTxBatcher(types.NodeID("peer1"), 10, 1<<20, sender, nil, nil)
+	ctx := context.Background()
+
+	b.QueueTx(ctx, []byte("a"))
+	require.Empty(t, sender.txs)
+
+	b.Flush(ctx)
+	require.Equal(t, [][]byte{[]byte("a")}, sender.txs[0])
+
+	// Flushing with nothing queued is a no-op.
+	b.Flush(ctx)
+	require.Len(t, sender.txs, 1)
+}
+
+func TestTxBatcherQueueHashFlushesIndependentlyOfTxBatch(t *testing.T) {
+	sender := &fakeGossipSender{}
+	b := newTxBatcher(types.NodeID("peer1"), 1, 1<<20, sender, nil, nil)
+	ctx := context.Background()
+
+	b.QueueHash(ctx, []byte("hash-a"))
+	require.Equal(t, [][]byte{[]byte("hash-a")}, sender.announces[0])
+	require.Empty(t, sender.txs)
+}
+
+func TestTxBatcherFlushSendsBothHashAndTxBatches(t *testing.T) {
+	sender := &fakeGossipSender{}
+	b := newTxBatcher(types.NodeID("peer1"), 10, 1<<20, sender, nil, nil)
+	ctx := context.Background()
+
+	b.QueueHash(ctx, []byte("hash-a"))
+	b.QueueTx(ctx, []byte("tx-a"))
+	b.Flush(ctx)
+
+	require.Equal(t, [][]byte{[]byte("hash-a")}, sender.announces[0])
+	require.Equal(t, [][]byte{[]byte("tx-a")}, sender.txs[0])
+}
+
+// fakePeerManager is a minimal PeerManager/PeerEvicter double for exercising
+// Reactor.shouldGossipToPeer without a real p2p stack.
+type fakePeerManager struct {
+	height  int64
+	evicted []types.NodeID
+}
+
+func (m *fakePeerManager) GetHeight(types.NodeID) int64 { return m.height }
+
+func (m *fakePeerManager) EvictPeer(peerID types.NodeID, _ string) {
+	m.evicted = append(m.evicted, peerID)
+}
+
+func TestShouldGossipToPeerSkipsFarBehindPeer(t *testing.T) {
+	peerMgr := &fakePeerManager{height: 1}
+	r := &Reactor{peerMgr: peerMgr, scorer: NewEWMAScorer(nil)}
+
+	require.False(t, r.shouldGossipToPeer(types.NodeID("peer1"), 10))
+}
+
+func TestShouldGossipToPeerAllowsCaughtUpPeer(t *testing.T) {
+	peerMgr := &fakePeerManager{height: 9}
+	r := &Reactor{peerMgr: peerMgr, scorer: NewEWMAScorer(nil)}
+
+	require.True(t, r.shouldGossipToPeer(types.NodeID("peer1"), 10))
+}
+
+func TestShouldGossipToPeerEvictsBelowEvictScore(t *testing.T) {
+	scorer := NewEWMAScorer(nil)
+	peer := types.NodeID("peer1")
+
+	// Drive the score below peerEvictScore. Each signal's penalty is capped,
+	// so failures alone bottom out at 0.6; saturate all four signals.
+	for i := 0; i < 50; i++ {
+		scorer.RecordCheckTxFailure(peer)
+		scorer.RecordDuplicateReceive(peer)
+		scorer.RecordLatency(peer, 2*time.Second)
+		scorer.RecordHeightLag(peer, 20)
+	}
+
+	peerMgr := &fakePeerManager{height: 0}
+	r := &Reactor{peerMgr: peerMgr, scorer: scorer}
+
+	require.False(t, r.shouldGossipToPeer(peer, 10))
+	require.Contains(t, peerMgr.evicted, peer)
+}
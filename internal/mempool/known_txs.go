@@ -0,0 +1,107 @@
+package mempool
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// maxKnownTxsPerPeer bounds the number of tx hashes we remember having
+// announced to (or requested from) a single peer. Once exceeded, the least
+// recently touched hash is evicted, favoring a false "unknown" (which only
+// costs a redundant announce/request) over unbounded memory growth.
+const maxKnownTxsPerPeer = 100000
+
+// knownTxCache is a per-peer bounded LRU set of tx hashes, layered on top of
+// the existing IDs/txStore.TxHasPeer bookkeeping. It is used by the
+// announce/request gossip protocol to avoid re-announcing a hash to a peer
+// that has already seen (or already asked for) it.
+type knownTxCache struct {
+	mtx      sync.Mutex
+	max      int
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+func newKnownTxCache(max int) *knownTxCache {
+	if max <= 0 {
+		max = maxKnownTxsPerPeer
+	}
+
+	return &knownTxCache{
+		max:      max,
+		order:    list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+// Has reports whether hash has already been recorded as known.
+func (c *knownTxCache) Has(hash []byte) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	key := string(hash)
+	elem, ok := c.elements[key]
+	if ok {
+		c.order.MoveToFront(elem)
+	}
+
+	return ok
+}
+
+// Add records hash as known, evicting the least recently used entry if the
+// cache is at capacity.
+func (c *knownTxCache) Add(hash []byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	key := string(hash)
+	if elem, ok := c.elements[key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	elem := c.order.PushFront(key)
+	c.elements[key] = elem
+
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+
+		c.order.Remove(oldest)
+		delete(c.elements, oldest.Value.(string))
+	}
+}
+
+// knownTxRegistry tracks a knownTxCache per peer.
+type knownTxRegistry struct {
+	mtx   sync.Mutex
+	peers map[types.NodeID]*knownTxCache
+}
+
+func newKnownTxRegistry() *knownTxRegistry {
+	return &knownTxRegistry{peers: make(map[types.NodeID]*knownTxCache)}
+}
+
+func (r *knownTxRegistry) forPeer(peerID types.NodeID) *knownTxCache {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	c, ok := r.peers[peerID]
+	if !ok {
+		c = newKnownTxCache(maxKnownTxsPerPeer)
+		r.peers[peerID] = c
+	}
+
+	return c
+}
+
+func (r *knownTxRegistry) removePeer(peerID types.NodeID) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	delete(r.peers, peerID)
+}
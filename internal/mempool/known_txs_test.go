@@ -0,0 +1,52 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestKnownTxCacheHasAndAdd(t *testing.T) {
+	c := newKnownTxCache(2)
+
+	require.False(t, c.Has([]byte("a")))
+	c.Add([]byte("a"))
+	require.True(t, c.Has([]byte("a")))
+}
+
+func TestKnownTxCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newKnownTxCache(2)
+
+	c.Add([]byte("a"))
+	c.Add([]byte("b"))
+
+	// Touch "a" so "b" becomes the least recently used.
+	require.True(t, c.Has([]byte("a")))
+
+	c.Add([]byte("c"))
+
+	require.True(t, c.Has([]byte("a")))
+	require.False(t, c.Has([]byte("b")))
+	require.True(t, c.Has([]byte("c")))
+}
+
+func TestKnownTxRegistryIsolatesPeers(t *testing.T) {
+	r := newKnownTxRegistry()
+
+	r.forPeer(types.NodeID("peer1")).Add([]byte("a"))
+
+	require.True(t, r.forPeer(types.NodeID("peer1")).Has([]byte("a")))
+	require.False(t, r.forPeer(types.NodeID("peer2")).Has([]byte("a")))
+}
+
+func TestKnownTxRegistryRemovePeerDropsCache(t *testing.T) {
+	r := newKnownTxRegistry()
+	peer := types.NodeID("peer1")
+
+	r.forPeer(peer).Add([]byte("a"))
+	r.removePeer(peer)
+
+	require.False(t, r.forPeer(peer).Has([]byte("a")))
+}
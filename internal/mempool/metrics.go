@@ -0,0 +1,81 @@
+package mempool
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricsSubsystem is a prefix assigned to all metrics exported by this
+	// package.
+	MetricsSubsystem = "mempool"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// BatchSize tracks the number of txs included in each outbound gossip
+	// batch sent to a peer.
+	BatchSize metrics.Histogram
+
+	// PeerScore tracks the current PeerScorer reputation score, labeled by
+	// peer_id, for each connected peer.
+	PeerScore metrics.Gauge
+
+	// PeerBytesIn counts inbound tx bytes received from each peer, labeled
+	// by peer_id, regardless of whether they were accepted or dropped for
+	// exceeding a rate limit.
+	PeerBytesIn metrics.Counter
+
+	// PeerTxsDropped counts txs dropped from each peer, labeled by peer_id,
+	// for exceeding its per-peer or the global inbound rate limit.
+	PeerTxsDropped metrics.Counter
+}
+
+// PrometheusMetrics returns Metrics built using Prometheus client library.
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+
+	return &Metrics{
+		BatchSize: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "batch_size",
+			Help:      "Number of txs included in each outbound gossip batch.",
+			Buckets:   stdprometheus.LinearBuckets(1, 2, 10),
+		}, labels).With(labelsAndValues...),
+		PeerScore: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_score",
+			Help:      "Current PeerScorer reputation score for a peer, in [0, 1].",
+		}, append(labels, "peer_id")).With(labelsAndValues...),
+		PeerBytesIn: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_bytes_in",
+			Help:      "Cumulative inbound tx bytes received from a peer.",
+		}, append(labels, "peer_id")).With(labelsAndValues...),
+		PeerTxsDropped: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_txs_dropped",
+			Help:      "Cumulative txs dropped from a peer for exceeding a rate limit.",
+		}, append(labels, "peer_id")).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics, useful for testing or when metrics are
+// disabled.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		BatchSize:      discard.NewHistogram(),
+		PeerScore:      discard.NewGauge(),
+		PeerBytesIn:    discard.NewCounter(),
+		PeerTxsDropped: discard.NewCounter(),
+	}
+}
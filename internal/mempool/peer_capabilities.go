@@ -0,0 +1,61 @@
+package mempool
+
+import (
+	"sync"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// peerCapabilities tracks, per peer, whether it's expected to understand
+// the short-hash announce/request gossip protocol (TxAnnounce/TxRequest),
+// so GossipModeAnnounce/GossipModeHybrid can fall back to pushing full Txs
+// to peers that don't.
+//
+// Ideally this would be negotiated once up front, as a capability bit in
+// the initial peer handshake (NodeInfo), rather than assumed here. That
+// requires a handshake change in the p2p layer this package doesn't own,
+// so in the meantime the Reactor marks a peer capable optimistically as
+// soon as it comes up, for any peer we ourselves intend to use the
+// protocol with (see processPeerUpdate) — without this, two peers that
+// both only ever infer support reactively from a received TxAnnounce/
+// TxRequest can never send the other one first, and both fall back to
+// pushing full Txs forever. Receiving either message from a peer is kept
+// as a confirming signal on top of that (MarkAnnounceCapable is
+// idempotent), so a peer that's later added with push-only intent still
+// gets recognized once it actually demonstrates support.
+type peerCapabilities struct {
+	mtx      sync.Mutex
+	announce map[types.NodeID]bool
+}
+
+func newPeerCapabilities() *peerCapabilities {
+	return &peerCapabilities{announce: make(map[types.NodeID]bool)}
+}
+
+// MarkAnnounceCapable records that peerID understands the announce/request
+// protocol.
+func (c *peerCapabilities) MarkAnnounceCapable(peerID types.NodeID) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.announce[peerID] = true
+}
+
+// SupportsAnnounce reports whether peerID has been marked as understanding
+// the announce/request protocol, optimistically or after it sent one of
+// its messages.
+func (c *peerCapabilities) SupportsAnnounce(peerID types.NodeID) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.announce[peerID]
+}
+
+// RemovePeer discards any capability state held for peerID, e.g. on
+// disconnect.
+func (c *peerCapabilities) RemovePeer(peerID types.NodeID) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	delete(c.announce, peerID)
+}
@@ -0,0 +1,25 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPeerCapabilitiesDefaultsToUnsupported(t *testing.T) {
+	c := newPeerCapabilities()
+	require.False(t, c.SupportsAnnounce(types.NodeID("peer1")))
+}
+
+func TestPeerCapabilitiesMarkAndRemove(t *testing.T) {
+	c := newPeerCapabilities()
+	peer := types.NodeID("peer1")
+
+	c.MarkAnnounceCapable(peer)
+	require.True(t, c.SupportsAnnounce(peer))
+
+	c.RemovePeer(peer)
+	require.False(t, c.SupportsAnnounce(peer))
+}
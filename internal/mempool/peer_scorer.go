@@ -0,0 +1,254 @@
+package mempool
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// Score thresholds used by the Reactor to decide how to treat a peer during
+// gossip. These are intentionally conservative defaults; operators can swap
+// in their own PeerScorer implementation for different behavior.
+const (
+	// peerSkipScore is the score below which we skip sending to a peer
+	// entirely for a gossip cycle, relying on it to catch up via other
+	// peers or a later cycle.
+	peerSkipScore = 0.1
+
+	// peerDelayScore is the score below which we still send, but only after
+	// a short backoff, to give well-behaved peers priority.
+	peerDelayScore = 0.4
+
+	// peerEvictScore is the score below which we ask the PeerManager to
+	// consider evicting the peer outright.
+	peerEvictScore = 0.05
+)
+
+// ewmaDecayHalfLife controls how quickly a peer's failureRate and
+// duplicateRate forget a past event once it stops recurring: each signal's
+// contribution halves roughly every ewmaDecayHalfLife of idle time. Without
+// this, a single CheckTx failure or duplicate delivery would depress a
+// peer's score forever, since RecordCheckTxFailure/RecordDuplicateReceive
+// are the only things that ever touch those fields.
+const ewmaDecayHalfLife = 30 * time.Second
+
+// PeerScorer assigns a reputation score in [0, 1] to a peer based on
+// observed behavior: gossip latency, CheckTx failures for txs it sent us,
+// duplicate tx deliveries, and how far behind it is in height. Higher is
+// better. Implementations must be safe for concurrent use.
+type PeerScorer interface {
+	// Score returns the current reputation score for peerID. Unknown peers
+	// should score neutrally (around 0.5) rather than be penalized.
+	Score(peerID types.NodeID) float64
+
+	// RecordLatency records a round-trip latency sample for peerID.
+	RecordLatency(peerID types.NodeID, d time.Duration)
+
+	// RecordCheckTxFailure records that a tx originating from peerID was
+	// rejected by CheckTx.
+	RecordCheckTxFailure(peerID types.NodeID)
+
+	// RecordDuplicateReceive records that peerID sent us a tx we already had.
+	RecordDuplicateReceive(peerID types.NodeID)
+
+	// RecordHeightLag records how many blocks behind peerID appears to be,
+	// based on the height of txs we're gossiping to it versus its reported
+	// height. A more negative or smaller lag is better; 0 or below means
+	// the peer is caught up.
+	RecordHeightLag(peerID types.NodeID, lag int64)
+
+	// RemovePeer discards any state held for peerID, e.g. on disconnect.
+	RemovePeer(peerID types.NodeID)
+}
+
+// PeerEvicter is implemented by a PeerManager that can act on a
+// sufficiently-bad PeerScorer score by disconnecting the peer. It is
+// optional: a Reactor's PeerManager that does not implement it simply never
+// has peers evicted based on score.
+type PeerEvicter interface {
+	EvictPeer(peerID types.NodeID, reason string)
+}
+
+// ewmaPeerState tracks the exponentially-weighted moving averages backing
+// the default scorer for a single peer. Each signal tracks its own
+// "have we seen a first sample yet" state (latencySamples, failureSamples,
+// duplicateSamples, heightLagSamples) rather than sharing one counter:
+// RecordHeightLag fires on essentially every broadcastTxRoutine loop
+// iteration, so a shared counter would make it virtually impossible for a
+// peer's first real CheckTx failure or duplicate delivery to ever land as
+// the "adopt this sample directly" case ewma() is meant to provide.
+type ewmaPeerState struct {
+	latencyMS        float64
+	failureRate      float64
+	duplicateRate    float64
+	heightLag        float64
+	latencySamples   int
+	failureSamples   int
+	duplicateSamples int
+	heightLagSamples int
+
+	// lastUpdate is when decay was last applied, so decay() can scale by
+	// how long failureRate/duplicateRate have gone un-reinforced.
+	lastUpdate time.Time
+}
+
+// decay folds elapsed idle time since the last update into failureRate and
+// duplicateRate, exponentially decaying them toward zero. latencyMS and
+// heightLag are left alone: they track a peer's current behavior rather
+// than accumulating a count of past incidents, so they're already
+// self-correcting as new samples arrive.
+func (st *ewmaPeerState) decay(now time.Time) {
+	if st.lastUpdate.IsZero() {
+		st.lastUpdate = now
+		return
+	}
+
+	elapsed := now.Sub(st.lastUpdate)
+	if elapsed <= 0 {
+		return
+	}
+	st.lastUpdate = now
+
+	factor := math.Exp2(-float64(elapsed) / float64(ewmaDecayHalfLife))
+	st.failureRate *= factor
+	st.duplicateRate *= factor
+}
+
+// ewmaScorer is the default PeerScorer. It maintains an EWMA per signal per
+// peer and combines them into a single score. alpha controls how quickly
+// new samples dominate the average; a larger alpha forgets history faster.
+type ewmaScorer struct {
+	mtx   sync.Mutex
+	alpha float64
+	peers map[types.NodeID]*ewmaPeerState
+
+	metrics *Metrics
+}
+
+// NewEWMAScorer returns the default PeerScorer implementation.
+func NewEWMAScorer(metrics *Metrics) PeerScorer {
+	return &ewmaScorer{
+		alpha:   0.2,
+		peers:   make(map[types.NodeID]*ewmaPeerState),
+		metrics: metrics,
+	}
+}
+
+func (s *ewmaScorer) state(peerID types.NodeID) *ewmaPeerState {
+	st, ok := s.peers[peerID]
+	if !ok {
+		st = &ewmaPeerState{}
+		s.peers[peerID] = st
+	}
+	return st
+}
+
+func (s *ewmaScorer) Score(peerID types.NodeID) float64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	st, ok := s.peers[peerID]
+	if !ok {
+		return 0.5
+	}
+
+	st.decay(time.Now())
+
+	// Normalize latency against a 1s ceiling; anything at or beyond that is
+	// treated as equally bad.
+	latencyPenalty := st.latencyMS / 1000
+	if latencyPenalty > 1 {
+		latencyPenalty = 1
+	}
+
+	// Normalize height lag against a 10-block ceiling.
+	heightPenalty := st.heightLag / 10
+	if heightPenalty < 0 {
+		heightPenalty = 0
+	} else if heightPenalty > 1 {
+		heightPenalty = 1
+	}
+
+	score := 1 - (0.4*st.failureRate + 0.25*st.duplicateRate + 0.15*latencyPenalty + 0.2*heightPenalty)
+	if score < 0 {
+		score = 0
+	} else if score > 1 {
+		score = 1
+	}
+
+	if s.metrics != nil {
+		s.metrics.PeerScore.With("peer_id", string(peerID)).Set(score)
+	}
+
+	return score
+}
+
+func (s *ewmaScorer) RecordLatency(peerID types.NodeID, d time.Duration) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	st := s.state(peerID)
+	st.decay(now)
+	st.latencyMS = ewma(st.latencyMS, float64(d.Milliseconds()), s.alpha, st.latencySamples)
+	st.latencySamples++
+}
+
+func (s *ewmaScorer) RecordCheckTxFailure(peerID types.NodeID) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	st := s.state(peerID)
+	st.decay(now)
+	st.failureRate = ewma(st.failureRate, 1, s.alpha, st.failureSamples)
+	st.failureSamples++
+}
+
+func (s *ewmaScorer) RecordDuplicateReceive(peerID types.NodeID) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	st := s.state(peerID)
+	st.decay(now)
+	st.duplicateRate = ewma(st.duplicateRate, 1, s.alpha, st.duplicateSamples)
+	st.duplicateSamples++
+}
+
+func (s *ewmaScorer) RecordHeightLag(peerID types.NodeID, lag int64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if lag < 0 {
+		lag = 0
+	}
+
+	now := time.Now()
+	st := s.state(peerID)
+	st.decay(now)
+	st.heightLag = ewma(st.heightLag, float64(lag), s.alpha, st.heightLagSamples)
+	st.heightLagSamples++
+}
+
+func (s *ewmaScorer) RemovePeer(peerID types.NodeID) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.peers, peerID)
+}
+
+// ewma folds sample into avg with weight alpha, decaying the contribution of
+// older samples. On the first sample it simply adopts the value rather than
+// blending with the zero-value average. It only ever moves avg toward a
+// recorded sample; see ewmaPeerState.decay for how failureRate and
+// duplicateRate separately decay toward zero during stretches with no new
+// samples.
+func ewma(avg, sample, alpha float64, samples int) float64 {
+	if samples == 0 {
+		return sample
+	}
+	return alpha*sample + (1-alpha)*avg
+}
@@ -0,0 +1,91 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestEWMAScorerDefaultsToNeutral(t *testing.T) {
+	s := NewEWMAScorer(NopMetrics())
+	require.Equal(t, 0.5, s.Score(types.NodeID("unknown")))
+}
+
+func TestEWMAScorerPenalizesFailuresAndDuplicates(t *testing.T) {
+	s := NewEWMAScorer(NopMetrics())
+	peer := types.NodeID("peer1")
+
+	// Give the peer a clean, caught-up record first, so before is its own
+	// baseline rather than the fixed 0.5 returned for an unknown peer.
+	s.RecordHeightLag(peer, 0)
+	before := s.Score(peer)
+
+	s.RecordCheckTxFailure(peer)
+	require.Less(t, s.Score(peer), before)
+
+	s.RecordDuplicateReceive(peer)
+	require.Less(t, s.Score(peer), before)
+}
+
+func TestEWMAScorerPenalizesHeightLag(t *testing.T) {
+	s := NewEWMAScorer(NopMetrics())
+	peer := types.NodeID("peer1")
+
+	// Start from a caught-up peer, not the fixed 0.5 of an unknown one.
+	s.RecordHeightLag(peer, 0)
+	before := s.Score(peer)
+	s.RecordHeightLag(peer, 20)
+	require.Less(t, s.Score(peer), before)
+}
+
+func TestEWMAScorerDecaysFailuresOverTime(t *testing.T) {
+	scorer := NewEWMAScorer(NopMetrics()).(*ewmaScorer)
+	peer := types.NodeID("peer1")
+
+	scorer.RecordCheckTxFailure(peer)
+	scoreRightAfter := scorer.Score(peer)
+
+	// Simulate the passage of several decay half-lives without pushing the
+	// clock forward for real.
+	scorer.mtx.Lock()
+	scorer.state(peer).lastUpdate = time.Now().Add(-10 * ewmaDecayHalfLife)
+	scorer.mtx.Unlock()
+
+	require.Greater(t, scorer.Score(peer), scoreRightAfter)
+}
+
+func TestEWMAScorerFirstFailureIsNotDilutedByUnrelatedSignals(t *testing.T) {
+	scorer := NewEWMAScorer(NopMetrics()).(*ewmaScorer)
+	peer := types.NodeID("peer1")
+
+	// RecordHeightLag fires on essentially every broadcastTxRoutine loop
+	// iteration, well before any failure or duplicate event. It must not
+	// advance failureRate's own first-sample bookkeeping, or the peer's
+	// first real CheckTx failure would be blended in at alpha weight
+	// instead of adopted directly.
+	for i := 0; i < 5; i++ {
+		scorer.RecordHeightLag(peer, 0)
+	}
+
+	scorer.RecordCheckTxFailure(peer)
+
+	scorer.mtx.Lock()
+	failureRate := scorer.state(peer).failureRate
+	scorer.mtx.Unlock()
+
+	require.Equal(t, 1.0, failureRate)
+}
+
+func TestEWMAScorerRemovePeerClearsState(t *testing.T) {
+	s := NewEWMAScorer(NopMetrics())
+	peer := types.NodeID("peer1")
+
+	s.RecordCheckTxFailure(peer)
+	require.NotEqual(t, 0.5, s.Score(peer))
+
+	s.RemovePeer(peer)
+	require.Equal(t, 0.5, s.Score(peer))
+}
@@ -0,0 +1,177 @@
+package mempool
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// priorityGossipAgingCycles is the number of times next() can pass over a
+// tx (pop it and have skip reject it) before its effective priority is
+// boosted. Only entries popped on the way to the chosen one are counted;
+// entries ranked below the chosen tx are not popped and do not age, so the
+// boost does not lift a low-priority tx above a steady stream of
+// higher-priority ones.
+const priorityGossipAgingCycles = 50
+
+// priorityGossipAgingBoost is added to a tx's effective priority for every
+// priorityGossipAgingCycles cycles it has been passed over.
+const priorityGossipAgingBoost = 1
+
+// priorityEntry wraps a WrappedTx with the bookkeeping the priority index
+// needs on top of WrappedTx.priority: its position in the heap, an arrival
+// sequence number for tiebreaking, and how many gossip cycles it has been
+// passed over (for aging).
+type priorityEntry struct {
+	tx     *WrappedTx
+	seq    int64
+	cycles int64
+	index  int
+}
+
+func (e *priorityEntry) effectivePriority() int64 {
+	return e.tx.priority + (e.cycles/priorityGossipAgingCycles)*priorityGossipAgingBoost
+}
+
+// priorityHeap is a container/heap.Interface max-heap ordered by effective
+// priority, tiebreaking on arrival order (older first) so same-priority txs
+// still gossip in roughly FIFO order.
+type priorityHeap []*priorityEntry
+
+func (h priorityHeap) Len() int { return len(h) }
+
+func (h priorityHeap) Less(i, j int) bool {
+	pi, pj := h[i].effectivePriority(), h[j].effectivePriority()
+	if pi != pj {
+		return pi > pj
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h priorityHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *priorityHeap) Push(x interface{}) {
+	e := x.(*priorityEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
+
+// priorityIndex is a secondary, priority-ordered index over the txs held by
+// TxMempool's primary FIFO CList, used to serve NextGossipTxByPriority. It
+// does not own the txs; entries are added/removed in lockstep with the
+// primary list as txs are inserted into, and evicted from, the mempool.
+type priorityIndex struct {
+	mtx     sync.Mutex
+	h       priorityHeap
+	entries map[string]*priorityEntry
+	nextSeq int64
+}
+
+func newPriorityIndex() *priorityIndex {
+	return &priorityIndex{entries: make(map[string]*priorityEntry)}
+}
+
+// Insert adds tx to the index. It is a no-op if tx is already present.
+func (idx *priorityIndex) Insert(tx *WrappedTx) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+
+	key := string(tx.hash)
+	if _, ok := idx.entries[key]; ok {
+		return
+	}
+
+	e := &priorityEntry{tx: tx, seq: idx.nextSeq}
+	idx.nextSeq++
+
+	heap.Push(&idx.h, e)
+	idx.entries[key] = e
+}
+
+// Remove discards tx from the index, if present.
+func (idx *priorityIndex) Remove(tx *WrappedTx) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+
+	key := string(tx.hash)
+	e, ok := idx.entries[key]
+	if !ok {
+		return
+	}
+
+	heap.Remove(&idx.h, e.index)
+	delete(idx.entries, key)
+}
+
+// next returns the highest effective-priority tx for which skip returns
+// false, bumping the cycle count (and thus, over time, the effective
+// priority) of every entry it passes over along the way. It recomputes its
+// answer from the live heap on every call rather than holding a cursor into
+// it, so it is inherently safe against concurrent insertion/removal: there
+// is no stale position to re-seek from, only a fresh query each time.
+//
+// It only pops entries off the heap while looking for one skip accepts,
+// rather than draining the whole thing: the common case (the top entry
+// isn't skipped) is a single O(log M) pop and push, and the cost otherwise
+// scales with however many entries were actually passed over, not with the
+// size of the heap.
+func (idx *priorityIndex) next(skip func(*WrappedTx) bool) *WrappedTx {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+
+	var (
+		chosen     *priorityEntry
+		passedOver []*priorityEntry
+	)
+
+	for idx.h.Len() > 0 {
+		e := heap.Pop(&idx.h).(*priorityEntry)
+		if !skip(e.tx) {
+			chosen = e
+			break
+		}
+		passedOver = append(passedOver, e)
+	}
+
+	for _, e := range passedOver {
+		e.cycles++
+		heap.Push(&idx.h, e)
+	}
+
+	if chosen == nil {
+		return nil
+	}
+
+	chosen.cycles = 0
+	heap.Push(&idx.h, chosen)
+
+	return chosen.tx
+}
+
+// NextGossipTxByPriority returns the highest-priority tx that peerMempoolID
+// has not yet been sent, or nil if none is eligible. It is the
+// priority-ordered counterpart to TxMempool's existing FIFO NextGossipTx.
+//
+// Unlike the FIFO routine, there is no cursor advancing past a tx once it's
+// been handed to the caller, so txStore.TxHasPeer alone isn't enough to keep
+// next() from handing back the same top-priority tx forever: alreadySent
+// lets the caller supply its own delivered-to-peer bookkeeping (e.g. a
+// knownTxCache) to skip over it too.
+func (txmp *TxMempool) NextGossipTxByPriority(peerMempoolID uint16, alreadySent func(*WrappedTx) bool) *WrappedTx {
+	return txmp.priorityIndex.next(func(tx *WrappedTx) bool {
+		return txmp.txStore.TxHasPeer(tx.hash, peerMempoolID) || (alreadySent != nil && alreadySent(tx))
+	})
+}
@@ -0,0 +1,89 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mkPriorityTx(hash string, priority int64) *WrappedTx {
+	return &WrappedTx{hash: []byte(hash), priority: priority, tx: []byte(hash)}
+}
+
+func TestPriorityIndexOrdersByPriority(t *testing.T) {
+	idx := newPriorityIndex()
+
+	idx.Insert(mkPriorityTx("low", 1))
+	idx.Insert(mkPriorityTx("high", 10))
+	idx.Insert(mkPriorityTx("mid", 5))
+
+	// next has no cursor: it keeps returning the top entry until the caller
+	// skips it, so skip whatever has already been handed out.
+	sent := make(map[string]bool)
+	skipSent := func(tx *WrappedTx) bool { return sent[string(tx.hash)] }
+	nextHash := func() []byte {
+		tx := idx.next(skipSent)
+		sent[string(tx.hash)] = true
+		return tx.hash
+	}
+
+	require.Equal(t, []byte("high"), nextHash())
+	require.Equal(t, []byte("mid"), nextHash())
+	require.Equal(t, []byte("low"), nextHash())
+}
+
+func TestPriorityIndexNextSkipsOverSelectedEntries(t *testing.T) {
+	idx := newPriorityIndex()
+
+	idx.Insert(mkPriorityTx("high", 10))
+	idx.Insert(mkPriorityTx("mid", 5))
+
+	skipHigh := func(tx *WrappedTx) bool { return string(tx.hash) == "high" }
+
+	got := idx.next(skipHigh)
+	require.Equal(t, []byte("mid"), got.hash)
+
+	// "high" should still be present for a caller that doesn't skip it.
+	got = idx.next(func(*WrappedTx) bool { return false })
+	require.Equal(t, []byte("high"), got.hash)
+}
+
+func TestPriorityIndexNextReturnsNilWhenAllSkipped(t *testing.T) {
+	idx := newPriorityIndex()
+	idx.Insert(mkPriorityTx("only", 1))
+
+	require.Nil(t, idx.next(func(*WrappedTx) bool { return true }))
+
+	// Passing over an entry must not lose it.
+	require.NotNil(t, idx.next(func(*WrappedTx) bool { return false }))
+}
+
+func TestPriorityIndexAgingBoostsPassedOverEntries(t *testing.T) {
+	idx := newPriorityIndex()
+
+	idx.Insert(mkPriorityTx("high", 10))
+	idx.Insert(mkPriorityTx("low", 1))
+
+	// Repeatedly pass over "high" without ever choosing it, until its
+	// accumulated cycles raise its effective priority above its base of 10.
+	skipHigh := func(tx *WrappedTx) bool { return string(tx.hash) == "high" }
+	for i := 0; i < priorityGossipAgingCycles*9+1; i++ {
+		got := idx.next(skipHigh)
+		require.Equal(t, []byte("low"), got.hash)
+	}
+
+	e := idx.entries[string([]byte("high"))]
+	require.Greater(t, e.effectivePriority(), int64(10))
+}
+
+func TestPriorityIndexRemove(t *testing.T) {
+	idx := newPriorityIndex()
+	tx := mkPriorityTx("only", 1)
+	idx.Insert(tx)
+
+	idx.Remove(tx)
+	require.Nil(t, idx.next(func(*WrappedTx) bool { return false }))
+
+	// Removing something already absent is a no-op.
+	idx.Remove(tx)
+}
@@ -0,0 +1,137 @@
+package mempool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// tokenBucket is a minimal token-bucket rate limiter: capacity tokens are
+// available up front, refilled continuously at refillRate tokens/sec, and
+// an Allow call either spends n tokens or is rejected.
+type tokenBucket struct {
+	mtx        sync.Mutex
+	capacity   float64
+	tokens     float64
+	refillRate float64
+	last       time.Time
+}
+
+func newTokenBucket(capacity, refillRate float64) *tokenBucket {
+	return &tokenBucket{
+		capacity:   capacity,
+		tokens:     capacity,
+		refillRate: refillRate,
+		last:       time.Now(),
+	}
+}
+
+// Allow reports whether n tokens are available, spending them if so.
+func (b *tokenBucket) Allow(n float64) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.refillRate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens < n {
+		return false
+	}
+
+	b.tokens -= n
+	return true
+}
+
+// peerLimiter bounds both the byte rate and the tx rate accepted from a
+// single peer.
+type peerLimiter struct {
+	bytes *tokenBucket
+	txs   *tokenBucket
+}
+
+// Allow reports whether a tx of the given size is within both the byte and
+// tx quota for this peer. A nil bucket (quota left unconfigured) is treated
+// as unlimited.
+func (l *peerLimiter) Allow(txBytes int) bool {
+	if l.txs != nil && !l.txs.Allow(1) {
+		return false
+	}
+	if l.bytes != nil && !l.bytes.Allow(float64(txBytes)) {
+		return false
+	}
+	return true
+}
+
+// rateLimiterRegistry hands out a peerLimiter per peer, sized from the
+// MempoolConfig quotas, and enforces a global inbound byte quota shared
+// across all peers.
+type rateLimiterRegistry struct {
+	mtx   sync.Mutex
+	peers map[types.NodeID]*peerLimiter
+
+	peerBytesPerSec float64
+	peerTxsPerSec   float64
+
+	global *tokenBucket
+}
+
+func newRateLimiterRegistry(peerBytesPerSec, peerTxsPerSec, globalBytesPerSec float64) *rateLimiterRegistry {
+	r := &rateLimiterRegistry{
+		peers:           make(map[types.NodeID]*peerLimiter),
+		peerBytesPerSec: peerBytesPerSec,
+		peerTxsPerSec:   peerTxsPerSec,
+	}
+
+	if globalBytesPerSec > 0 {
+		// Allow a one-second burst up front, same as the per-peer buckets.
+		r.global = newTokenBucket(globalBytesPerSec, globalBytesPerSec)
+	}
+
+	return r
+}
+
+func (r *rateLimiterRegistry) forPeer(peerID types.NodeID) *peerLimiter {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	l, ok := r.peers[peerID]
+	if !ok {
+		l = &peerLimiter{}
+		if r.peerBytesPerSec > 0 {
+			l.bytes = newTokenBucket(r.peerBytesPerSec, r.peerBytesPerSec)
+		}
+		if r.peerTxsPerSec > 0 {
+			l.txs = newTokenBucket(r.peerTxsPerSec, r.peerTxsPerSec)
+		}
+		r.peers[peerID] = l
+	}
+
+	return l
+}
+
+func (r *rateLimiterRegistry) removePeer(peerID types.NodeID) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	delete(r.peers, peerID)
+}
+
+// Allow reports whether a tx of txBytes from peerID is within both that
+// peer's quota and the global inbound quota. A quota of 0 (bytes/sec or
+// txs/sec left unconfigured) is treated as unlimited.
+func (r *rateLimiterRegistry) Allow(peerID types.NodeID, txBytes int) bool {
+	if !r.forPeer(peerID).Allow(txBytes) {
+		return false
+	}
+
+	if r.global != nil && !r.global.Allow(float64(txBytes)) {
+		return false
+	}
+
+	return true
+}
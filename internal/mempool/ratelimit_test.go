@@ -0,0 +1,51 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestTokenBucketAllowsUpToCapacityThenRejects(t *testing.T) {
+	b := newTokenBucket(10, 0) // no refill, so capacity is a hard ceiling
+	require.True(t, b.Allow(4))
+	require.True(t, b.Allow(6))
+	require.False(t, b.Allow(1))
+}
+
+func TestRateLimiterRegistryUnlimitedWhenUnconfigured(t *testing.T) {
+	r := newRateLimiterRegistry(0, 0, 0)
+	peer := types.NodeID("peer1")
+
+	for i := 0; i < 1000; i++ {
+		require.True(t, r.Allow(peer, 1<<20))
+	}
+}
+
+func TestRateLimiterRegistryEnforcesPerPeerQuota(t *testing.T) {
+	r := newRateLimiterRegistry(100, 0, 0)
+	peer := types.NodeID("peer1")
+
+	require.True(t, r.Allow(peer, 100))
+	require.False(t, r.Allow(peer, 1))
+}
+
+func TestRateLimiterRegistryEnforcesGlobalQuotaAcrossPeers(t *testing.T) {
+	r := newRateLimiterRegistry(0, 0, 100)
+
+	require.True(t, r.Allow(types.NodeID("peer1"), 60))
+	require.False(t, r.Allow(types.NodeID("peer2"), 60))
+}
+
+func TestRateLimiterRegistryRemovePeerResetsQuota(t *testing.T) {
+	r := newRateLimiterRegistry(100, 0, 0)
+	peer := types.NodeID("peer1")
+
+	require.True(t, r.Allow(peer, 100))
+	require.False(t, r.Allow(peer, 1))
+
+	r.removePeer(peer)
+	require.True(t, r.Allow(peer, 100))
+}
@@ -23,6 +23,23 @@ var (
 	_ p2p.Wrapper     = (*protomem.Message)(nil)
 )
 
+// config.MempoolConfig (config/mempool.go) carries the MaxBatchBytes,
+// MaxBatchTxs, BatchFlushInterval, GossipMode, GossipOrder,
+// MaxPeerBytesPerSecond, MaxPeerTxsPerSecond, and
+// MaxGlobalInboundBytesPerSecond fields this package needs, and
+// proto/tendermint/mempool carries the TxAnnounce/TxRequest message types
+// on protomem.Message. proto/tendermint/mempool/types.go is a hand-written
+// stand-in for the generated types.pb.go `make proto-gen` would normally
+// produce from the .proto file in that directory; no protoc toolchain was
+// available to run it here. The one piece still missing is the node
+// wiring that constructs a *Metrics and passes it to NewReactor - that
+// lives in node/node.go, well outside this package's files.
+//
+// defaultBatchFlushInterval is used when MempoolConfig.BatchFlushInterval is
+// left unset (zero), so that per-peer batches still get flushed promptly
+// during quiet periods.
+const defaultBatchFlushInterval = 10 * time.Millisecond
+
 // PeerManager defines the interface contract required for getting necessary
 // peer information. This should eventually be replaced with a message-oriented
 // approach utilizing the p2p stack.
@@ -47,7 +64,8 @@ type Reactor struct {
 
 	mempoolCh   *p2p.Channel
 	peerUpdates *p2p.PeerUpdates
-	closeCh     chan struct{}
+
+	metrics *Metrics
 
 	// peerWG is used to coordinate graceful termination of all peer broadcasting
 	// goroutines.
@@ -57,8 +75,51 @@ type Reactor struct {
 	// Reactor. observePanic is called with the recovered value.
 	observePanic func(interface{})
 
+	// cancel cancels the context derived in OnStart from the one it was
+	// given, tearing down every goroutine the reactor spawned.
+	cancel context.CancelFunc
+
 	mtx          tmsync.Mutex
-	peerRoutines map[types.NodeID]*tmsync.Closer
+	peerRoutines map[types.NodeID]context.CancelFunc
+
+	// knownTxs tracks, per peer, which tx hashes have already been announced
+	// to (or requested from) that peer so the announce/request gossip mode
+	// never re-announces a hash needlessly.
+	knownTxs *knownTxRegistry
+
+	// scorer ranks peers by reputation so broadcastTxRoutine can prioritize
+	// well-behaved peers. Defaults to an EWMA-based implementation but is
+	// pluggable for tests or alternative strategies.
+	scorer PeerScorer
+
+	// limiter enforces per-peer and global inbound tx quotas before
+	// handleMempoolMessage calls CheckTx.
+	limiter *rateLimiterRegistry
+
+	// capabilities tracks which peers are marked as supporting the
+	// announce/request gossip protocol; GossipModeAnnounce/GossipModeHybrid
+	// push to peers that aren't marked. processPeerUpdate marks every new
+	// peer in those modes, so a connected peer is not checked for support
+	// before it is sent announces.
+	capabilities *peerCapabilities
+
+	// reqLatency times the round trip of a TxRequest we sent, feeding
+	// PeerScorer.RecordLatency once the matching Txs reply arrives.
+	reqLatency *requestLatencyTracker
+}
+
+// GossipMode values for MempoolConfig.GossipMode.
+const (
+	GossipModePush     = "push"
+	GossipModeAnnounce = "announce"
+	GossipModeHybrid   = "hybrid"
+)
+
+// wantsAnnounce reports whether cfg asks to announce hashes and wait for a
+// TxRequest rather than push the full tx body; GossipModeHybrid behaves
+// like announce here.
+func (r *Reactor) wantsAnnounce() bool {
+	return r.cfg.GossipMode == GossipModeAnnounce || r.cfg.GossipMode == GossipModeHybrid
 }
 
 // NewReactor returns a reference to a new reactor.
@@ -69,6 +130,7 @@ func NewReactor(
 	txmp *TxMempool,
 	mempoolCh *p2p.Channel,
 	peerUpdates *p2p.PeerUpdates,
+	metrics *Metrics,
 ) *Reactor {
 
 	r := &Reactor{
@@ -78,9 +140,18 @@ func NewReactor(
 		ids:          NewMempoolIDs(),
 		mempoolCh:    mempoolCh,
 		peerUpdates:  peerUpdates,
-		closeCh:      make(chan struct{}),
-		peerRoutines: make(map[types.NodeID]*tmsync.Closer),
+		peerRoutines: make(map[types.NodeID]context.CancelFunc),
 		observePanic: defaultObservePanic,
+		metrics:      metrics,
+		knownTxs:     newKnownTxRegistry(),
+		scorer:       NewEWMAScorer(metrics),
+		capabilities: newPeerCapabilities(),
+		reqLatency:   newRequestLatencyTracker(),
+		limiter: newRateLimiterRegistry(
+			float64(cfg.MaxPeerBytesPerSecond),
+			float64(cfg.MaxPeerTxsPerSecond),
+			float64(cfg.MaxGlobalInboundBytesPerSecond),
+		),
 	}
 
 	r.BaseService = *service.NewBaseService(logger, "Mempool", r)
@@ -89,13 +160,25 @@ func NewReactor(
 
 func defaultObservePanic(r interface{}) {}
 
+// SetPeerScorer overrides the default EWMA PeerScorer. It must be called
+// before OnStart.
+func (r *Reactor) SetPeerScorer(scorer PeerScorer) {
+	r.scorer = scorer
+}
+
 // GetChannelDescriptor produces an instance of a descriptor for this
 // package's required channels.
 func GetChannelDescriptor(cfg *config.MempoolConfig) *p2p.ChannelDescriptor {
-	largestTx := make([]byte, cfg.MaxTxBytes)
+	// The channel must be able to hold a full batch envelope, not just a
+	// single largest-tx message, now that broadcastTxRoutine coalesces
+	// multiple txs per send.
+	batchTxs := make([][]byte, batchCapacity(cfg))
+	for i := range batchTxs {
+		batchTxs[i] = make([]byte, cfg.MaxTxBytes)
+	}
 	batchMsg := protomem.Message{
 		Sum: &protomem.Message_Txs{
-			Txs: &protomem.Txs{Txs: [][]byte{largestTx}},
+			Txs: &protomem.Txs{Txs: batchTxs},
 		},
 	}
 
@@ -108,6 +191,25 @@ func GetChannelDescriptor(cfg *config.MempoolConfig) *p2p.ChannelDescriptor {
 	}
 }
 
+// batchCapacity returns the maximum number of txs that a single batch
+// envelope may contain according to cfg, bounded below by 1 so a
+// misconfigured node still advertises enough capacity for one tx.
+func batchCapacity(cfg *config.MempoolConfig) int {
+	maxTxs := cfg.MaxBatchTxs
+	if maxTxs <= 0 {
+		maxTxs = 1
+	}
+
+	if cfg.MaxTxBytes > 0 {
+		byBytes := cfg.MaxBatchBytes / cfg.MaxTxBytes
+		if byBytes > 0 && byBytes < maxTxs {
+			maxTxs = byBytes
+		}
+	}
+
+	return maxTxs
+}
+
 // OnStart starts separate go routines for each p2p Channel and listens for
 // envelopes on each. In addition, it also listens for peer updates and handles
 // messages on that p2p channel accordingly. The caller must be sure to execute
@@ -117,36 +219,28 @@ func (r *Reactor) OnStart(ctx context.Context) error {
 		r.Logger.Info("tx broadcasting is disabled")
 	}
 
+	ctx, r.cancel = context.WithCancel(ctx)
+
 	go r.processMempoolCh(ctx)
 	go r.processPeerUpdates(ctx)
 
 	return nil
 }
 
-// OnStop stops the reactor by signaling to all spawned goroutines to exit and
-// blocking until they all exit.
+// OnStop stops the reactor. It cancels the context passed to OnStart, which
+// every spawned goroutine (processMempoolCh, processPeerUpdates, and each
+// peer's broadcastTxRoutine) selects on, and waits for the per-peer
+// broadcasting goroutines to exit.
 func (r *Reactor) OnStop() {
-	r.mtx.Lock()
-	for _, c := range r.peerRoutines {
-		c.Close()
-	}
-	r.mtx.Unlock()
-
-	// wait for all spawned peer tx broadcasting goroutines to gracefully exit
+	r.cancel()
 	r.peerWG.Wait()
-
-	// Close closeCh to signal to all spawned goroutines to gracefully exit. All
-	// p2p Channels should execute Close().
-	close(r.closeCh)
-
-	<-r.peerUpdates.Done()
 }
 
 // handleMempoolMessage handles envelopes sent from peers on the MempoolChannel.
 // For every tx in the message, we execute CheckTx. It returns an error if an
 // empty set of txs are sent in an envelope or if we receive an unexpected
 // message type.
-func (r *Reactor) handleMempoolMessage(envelope p2p.Envelope) error {
+func (r *Reactor) handleMempoolMessage(ctx context.Context, envelope p2p.Envelope) error {
 	logger := r.Logger.With("peer", envelope.From)
 
 	switch msg := envelope.Message.(type) {
@@ -156,17 +250,102 @@ func (r *Reactor) handleMempoolMessage(envelope p2p.Envelope) error {
 			return errors.New("empty txs received from peer")
 		}
 
+		// If this fulfills a TxRequest we sent, this is the first point at
+		// which we can measure its round-trip latency.
+		if d, ok := r.reqLatency.Received(envelope.From); ok {
+			r.scorer.RecordLatency(envelope.From, d)
+		}
+
 		txInfo := TxInfo{SenderID: r.ids.GetForPeer(envelope.From)}
 		if len(envelope.From) != 0 {
 			txInfo.SenderNodeID = envelope.From
 		}
 
 		for _, tx := range protoTxs {
+			if r.metrics != nil {
+				r.metrics.PeerBytesIn.With("peer_id", string(envelope.From)).Add(float64(len(tx)))
+			}
+
+			if !r.limiter.Allow(envelope.From, len(tx)) {
+				// Drop the tx rather than running it through CheckTx, and
+				// penalize the peer's reputation rather than tearing down
+				// the whole envelope with a PeerError; a momentary burst
+				// shouldn't look like a protocol violation.
+				r.scorer.RecordCheckTxFailure(envelope.From)
+				if r.metrics != nil {
+					r.metrics.PeerTxsDropped.With("peer_id", string(envelope.From)).Add(1)
+				}
+				logger.Debug("dropping tx from peer: rate limit exceeded", "tx", fmt.Sprintf("%X", types.Tx(tx).Hash()))
+				continue
+			}
+
 			if err := r.mempool.CheckTx(context.Background(), types.Tx(tx), nil, txInfo); err != nil {
+				if errors.Is(err, ErrTxInCache) {
+					r.scorer.RecordDuplicateReceive(envelope.From)
+				} else {
+					r.scorer.RecordCheckTxFailure(envelope.From)
+				}
 				logger.Error("checktx failed for tx", "tx", fmt.Sprintf("%X", types.Tx(tx).Hash()), "err", err)
 			}
 		}
 
+	case *protomem.TxAnnounce:
+		hashes := msg.GetHashes()
+		if len(hashes) == 0 {
+			return errors.New("empty tx announce received from peer")
+		}
+
+		r.capabilities.MarkAnnounceCapable(envelope.From)
+
+		known := r.knownTxs.forPeer(envelope.From)
+
+		unknown := make([][]byte, 0, len(hashes))
+		for _, hash := range hashes {
+			if r.mempool.txStore.GetTxByHash(hash) != nil || known.Has(hash) {
+				continue
+			}
+
+			known.Add(hash)
+			unknown = append(unknown, hash)
+		}
+
+		if len(unknown) > 0 {
+			select {
+			case r.mempoolCh.Out <- p2p.Envelope{
+				To:      envelope.From,
+				Message: &protomem.TxRequest{Hashes: unknown},
+			}:
+				r.reqLatency.Sent(envelope.From)
+			case <-ctx.Done():
+			}
+		}
+
+	case *protomem.TxRequest:
+		hashes := msg.GetHashes()
+		if len(hashes) == 0 {
+			return errors.New("empty tx request received from peer")
+		}
+
+		r.capabilities.MarkAnnounceCapable(envelope.From)
+
+		txs := make([][]byte, 0, len(hashes))
+		for _, hash := range hashes {
+			if wtx := r.mempool.txStore.GetTxByHash(hash); wtx != nil {
+				txs = append(txs, wtx.tx)
+				r.knownTxs.forPeer(envelope.From).Add(hash)
+			}
+		}
+
+		if len(txs) > 0 {
+			select {
+			case r.mempoolCh.Out <- p2p.Envelope{
+				To:      envelope.From,
+				Message: &protomem.Txs{Txs: txs},
+			}:
+			case <-ctx.Done():
+			}
+		}
+
 	default:
 		return fmt.Errorf("received unknown message: %T", msg)
 	}
@@ -177,7 +356,7 @@ func (r *Reactor) handleMempoolMessage(envelope p2p.Envelope) error {
 // handleMessage handles an Envelope sent from a peer on a specific p2p Channel.
 // It will handle errors and any possible panics gracefully. A caller can handle
 // any error returned by sending a PeerError on the respective channel.
-func (r *Reactor) handleMessage(chID p2p.ChannelID, envelope p2p.Envelope) (err error) {
+func (r *Reactor) handleMessage(ctx context.Context, chID p2p.ChannelID, envelope p2p.Envelope) (err error) {
 	defer func() {
 		if e := recover(); e != nil {
 			r.observePanic(e)
@@ -194,7 +373,7 @@ func (r *Reactor) handleMessage(chID p2p.ChannelID, envelope p2p.Envelope) (err
 
 	switch chID {
 	case MempoolChannel:
-		err = r.handleMempoolMessage(envelope)
+		err = r.handleMempoolMessage(ctx, envelope)
 
 	default:
 		err = fmt.Errorf("unknown channel ID (%d) for envelope (%T)", chID, envelope.Message)
@@ -209,7 +388,7 @@ func (r *Reactor) processMempoolCh(ctx context.Context) {
 	for {
 		select {
 		case envelope := <-r.mempoolCh.In:
-			if err := r.handleMessage(r.mempoolCh.ID, envelope); err != nil {
+			if err := r.handleMessage(ctx, r.mempoolCh.ID, envelope); err != nil {
 				r.Logger.Error("failed to process message", "ch_id", r.mempoolCh.ID, "envelope", envelope, "err", err)
 				r.mempoolCh.Error <- p2p.PeerError{
 					NodeID: envelope.From,
@@ -218,9 +397,6 @@ func (r *Reactor) processMempoolCh(ctx context.Context) {
 			}
 		case <-ctx.Done():
 			return
-		case <-r.closeCh:
-			r.Logger.Debug("stopped listening on mempool channel; closing...")
-			return
 		}
 	}
 }
@@ -239,42 +415,56 @@ func (r *Reactor) processPeerUpdate(ctx context.Context, peerUpdate p2p.PeerUpda
 	switch peerUpdate.Status {
 	case p2p.PeerStatusUp:
 		// Do not allow starting new tx broadcast loops after reactor shutdown
-		// has been initiated. This can happen after we've manually closed all
-		// peer broadcast loops and closed r.closeCh, but the router still sends
-		// in-flight peer updates.
-		if !r.IsRunning() {
+		// has been initiated. This can happen after OnStop has cancelled ctx
+		// but the router still sends in-flight peer updates.
+		if !r.IsRunning() || ctx.Err() != nil {
 			return
 		}
 
 		if r.cfg.Broadcast {
-			// Check if we've already started a goroutine for this peer, if not we create
-			// a new done channel so we can explicitly close the goroutine if the peer
-			// is later removed, we increment the waitgroup so the reactor can stop
-			// safely, and finally start the goroutine to broadcast txs to that peer.
+			// Check if we've already started a goroutine for this peer, if
+			// not we derive a cancellable context so we can explicitly stop
+			// the goroutine if the peer is later removed, we increment the
+			// waitgroup so the reactor can stop safely, and finally start
+			// the goroutine to broadcast txs to that peer.
 			_, ok := r.peerRoutines[peerUpdate.NodeID]
 			if !ok {
-				closer := tmsync.NewCloser()
+				peerCtx, cancel := context.WithCancel(ctx)
 
-				r.peerRoutines[peerUpdate.NodeID] = closer
+				r.peerRoutines[peerUpdate.NodeID] = cancel
 				r.peerWG.Add(1)
 
 				r.ids.ReserveForPeer(peerUpdate.NodeID)
 
+				// Optimistically assume the peer supports the announce/request
+				// protocol if we intend to use it ourselves. Without this, two
+				// peers that both only infer support reactively from a
+				// received TxAnnounce/TxRequest never send one to each other
+				// first, and both fall back to full Txs pushes forever; see
+				// peerCapabilities for the full rationale.
+				if r.wantsAnnounce() {
+					r.capabilities.MarkAnnounceCapable(peerUpdate.NodeID)
+				}
+
 				// start a broadcast routine ensuring all txs are forwarded to the peer
-				go r.broadcastTxRoutine(ctx, peerUpdate.NodeID, closer)
+				go r.broadcastTxRoutine(peerCtx, peerUpdate.NodeID)
 			}
 		}
 
 	case p2p.PeerStatusDown:
 		r.ids.Reclaim(peerUpdate.NodeID)
+		r.knownTxs.removePeer(peerUpdate.NodeID)
+		r.scorer.RemovePeer(peerUpdate.NodeID)
+		r.limiter.removePeer(peerUpdate.NodeID)
+		r.capabilities.RemovePeer(peerUpdate.NodeID)
+		r.reqLatency.RemovePeer(peerUpdate.NodeID)
 
 		// Check if we've started a tx broadcasting goroutine for this peer.
-		// If we have, we signal to terminate the goroutine via the channel's closure.
-		// This will internally decrement the peer waitgroup and remove the peer
+		// If we have, cancel its context to signal it to terminate. This
+		// will internally decrement the peer waitgroup and remove the peer
 		// from the map of peer tx broadcasting goroutines.
-		closer, ok := r.peerRoutines[peerUpdate.NodeID]
-		if ok {
-			closer.Close()
+		if cancel, ok := r.peerRoutines[peerUpdate.NodeID]; ok {
+			cancel()
 		}
 	}
 }
@@ -291,20 +481,121 @@ func (r *Reactor) processPeerUpdates(ctx context.Context) {
 			return
 		case peerUpdate := <-r.peerUpdates.Updates():
 			r.processPeerUpdate(ctx, peerUpdate)
+		}
+	}
+}
 
-		case <-r.closeCh:
-			r.Logger.Debug("stopped listening on peer updates channel; closing...")
-			return
+// GossipOrderPriority configures MempoolConfig.GossipOrder to consume from
+// TxMempool's priority index instead of its default FIFO order.
+const GossipOrderPriority = "priority"
+
+func (r *Reactor) broadcastTxRoutine(ctx context.Context, peerID types.NodeID) {
+	if r.cfg.GossipOrder == GossipOrderPriority {
+		r.broadcastTxRoutinePriority(ctx, peerID)
+		return
+	}
+	r.broadcastTxRoutineFIFO(ctx, peerID)
+}
+
+// shouldGossipToPeer applies the height-lag and reputation gating shared by
+// both broadcastTxRoutine flavors: it records how far behind peerID is
+// relative to txHeight, sleeps to let the peer catch up (or other peers go
+// first) when warranted, and asks r.peerMgr to consider evicting a peer
+// whose score has fallen below peerEvictScore. It reports whether the
+// caller should go ahead and gossip to peerID this cycle.
+func (r *Reactor) shouldGossipToPeer(peerID types.NodeID, txHeight int64) bool {
+	if r.peerMgr != nil {
+		height := r.peerMgr.GetHeight(peerID)
+		if height > 0 {
+			r.scorer.RecordHeightLag(peerID, txHeight-height)
+			if height < txHeight-1 {
+				// allow for a lag of one block
+				time.Sleep(PeerCatchupSleepIntervalMS * time.Millisecond)
+				return false
+			}
+		}
+	}
+
+	if score := r.scorer.Score(peerID); score < peerSkipScore {
+		// Bad peer: don't bother sending this cycle, let it catch up from
+		// better-behaved peers instead.
+		if score < peerEvictScore {
+			if evicter, ok := r.peerMgr.(PeerEvicter); ok {
+				evicter.EvictPeer(peerID, "mempool: reputation score below threshold")
+			}
 		}
+		time.Sleep(PeerCatchupSleepIntervalMS * time.Millisecond)
+		return false
+	} else if score < peerDelayScore {
+		// Mediocre peer: still serve it, but after giving higher-score
+		// peers' goroutines a head start this cycle.
+		time.Sleep(PeerCatchupSleepIntervalMS * time.Millisecond / 2)
 	}
+
+	return true
 }
 
-func (r *Reactor) broadcastTxRoutine(ctx context.Context, peerID types.NodeID, closer *tmsync.Closer) {
+// queueForPeer decides whether memTx should be announced or pushed to
+// peerID and, if so, queues it into batcher accordingly. It's shared by
+// both broadcastTxRoutine flavors. A tx is skipped entirely if peerID is
+// already known to be its origin (so we never echo a tx back to the peer
+// that gave it to us), or if it's already been delivered to peerID in
+// either form.
+func (r *Reactor) queueForPeer(
+	ctx context.Context,
+	batcher *txBatcher,
+	known *knownTxCache,
+	announce bool,
+	memTx *WrappedTx,
+	peerMempoolID uint16,
+) {
+	if r.mempool.txStore.TxHasPeer(memTx.hash, peerMempoolID) || known.Has(memTx.hash) {
+		return
+	}
+	known.Add(memTx.hash)
+
+	if announce {
+		batcher.QueueHash(ctx, memTx.hash)
+	} else {
+		batcher.QueueTx(ctx, memTx.tx)
+	}
+}
+
+func (r *Reactor) broadcastTxRoutineFIFO(ctx context.Context, peerID types.NodeID) {
 	peerMempoolID := r.ids.GetForPeer(peerID)
 	var nextGossipTx *clist.CElement
 
+	// wantAnnounce reports whether cfg asks us to announce hashes and wait
+	// for a TxRequest rather than push the full tx body. Whether we
+	// actually do so for this peer additionally depends on r.capabilities
+	// marking it as supporting the protocol (see peerCapabilities) - a peer
+	// that isn't marked gets the tx via a regular push.
+	wantAnnounce := r.wantsAnnounce()
+	known := r.knownTxs.forPeer(peerID)
+	batcher := newTxBatcher(peerID, r.cfg.MaxBatchTxs, r.cfg.MaxBatchBytes, channelGossipSender{r.mempoolCh}, r.metrics, r.Logger)
+
+	flushTimer := time.NewTimer(r.flushInterval())
+	defer flushTimer.Stop()
+
+	resetFlushTimer := func() {
+		if !flushTimer.Stop() {
+			select {
+			case <-flushTimer.C:
+			default:
+			}
+		}
+		flushTimer.Reset(r.flushInterval())
+	}
+
+	flush := func() {
+		batcher.Flush(ctx)
+		resetFlushTimer()
+	}
+
 	// remove the peer ID from the map of routines and mark the waitgroup as done
 	defer func() {
+		flush()
+
 		r.mtx.Lock()
 		delete(r.peerRoutines, peerID)
 		r.mtx.Unlock()
@@ -336,69 +627,125 @@ func (r *Reactor) broadcastTxRoutine(ctx context.Context, peerID types.NodeID, c
 					continue
 				}
 
-			case <-closer.Done():
-				// The peer is marked for removal via a PeerUpdate as the doneCh was
-				// explicitly closed to signal we should exit.
-				return
+			case <-flushTimer.C:
+				flush()
+				continue
 
 			case <-ctx.Done():
 				return
-
-			case <-r.closeCh:
-				// The reactor has signaled that we are stopped and thus we should
-				// implicitly exit this peer's goroutine.
-				return
 			}
 		}
 
 		memTx := nextGossipTx.Value.(*WrappedTx)
 
-		if r.peerMgr != nil {
-			height := r.peerMgr.GetHeight(peerID)
-			if height > 0 && height < memTx.height-1 {
-				// allow for a lag of one block
-				time.Sleep(PeerCatchupSleepIntervalMS * time.Millisecond)
-				continue
-			}
+		if !r.shouldGossipToPeer(peerID, memTx.height) {
+			continue
 		}
 
-		// NOTE: Transaction batching was disabled due to:
-		// https://github.com/tendermint/tendermint/issues/5796
-		if ok := r.mempool.txStore.TxHasPeer(memTx.hash, peerMempoolID); !ok {
-			// Send the mempool tx to the corresponding peer. Note, the peer may be
-			// behind and thus would not be able to process the mempool tx correctly.
+		announce := wantAnnounce && r.capabilities.SupportsAnnounce(peerID)
+		r.queueForPeer(ctx, batcher, known, announce, memTx, peerMempoolID)
+
+		// Always advance past memTx now that we've decided what to do with
+		// it, regardless of whether this cycle ended in an eager flush, a
+		// timer flush, or neither; otherwise a flush driven by flushTimer.C
+		// would leave nextGossipTx pointing at the same element and we'd
+		// re-queue it forever. Next is non-blocking: it returns nil if the
+		// following element doesn't exist yet, and the nil branch above then
+		// waits for the mempool to hold a tx (or for the next flush) and
+		// restarts from the front via NextGossipTx; queueForPeer skips txs
+		// this peer already has.
+		nextGossipTx = nextGossipTx.Next()
+	}
+}
+
+// broadcastTxRoutinePriority mirrors broadcastTxRoutineFIFO, but draws from
+// TxMempool's priority index via NextGossipTxByPriority instead of walking
+// the FIFO CList, so that high-priority txs propagate to this peer first.
+// Unlike the CList cursor, the priority index has no notion of "next after
+// here" to wait on; each cycle simply re-queries it, which is what makes it
+// safe against concurrent insertion/removal (see priorityIndex.next).
+func (r *Reactor) broadcastTxRoutinePriority(ctx context.Context, peerID types.NodeID) {
+	peerMempoolID := r.ids.GetForPeer(peerID)
+
+	// wantAnnounce reports whether cfg asks for announce/request gossip;
+	// per-peer it's additionally gated on r.capabilities, so an unmarked
+	// peer gets a push (see broadcastTxRoutineFIFO for the rationale).
+	wantAnnounce := r.wantsAnnounce()
+	known := r.knownTxs.forPeer(peerID)
+	batcher := newTxBatcher(peerID, r.cfg.MaxBatchTxs, r.cfg.MaxBatchBytes, channelGossipSender{r.mempoolCh}, r.metrics, r.Logger)
+
+	flushTimer := time.NewTimer(r.flushInterval())
+	defer flushTimer.Stop()
+
+	resetFlushTimer := func() {
+		if !flushTimer.Stop() {
 			select {
-			case r.mempoolCh.Out <- p2p.Envelope{
-				To: peerID,
-				Message: &protomem.Txs{
-					Txs: [][]byte{memTx.tx},
-				},
-			}:
-			case <-ctx.Done():
+			case <-flushTimer.C:
+			default:
 			}
-			r.Logger.Debug(
-				"gossiped tx to peer",
-				"tx", fmt.Sprintf("%X", memTx.tx.Hash()),
-				"peer", peerID,
-			)
 		}
+		flushTimer.Reset(r.flushInterval())
+	}
 
-		select {
-		case <-nextGossipTx.NextWaitChan():
-			nextGossipTx = nextGossipTx.Next()
+	flush := func() {
+		batcher.Flush(ctx)
+		resetFlushTimer()
+	}
 
-		case <-closer.Done():
-			// The peer is marked for removal via a PeerUpdate as the doneCh was
-			// explicitly closed to signal we should exit.
-			return
+	defer func() {
+		flush()
 
-		case <-ctx.Done():
-			return
+		r.mtx.Lock()
+		delete(r.peerRoutines, peerID)
+		r.mtx.Unlock()
+
+		r.peerWG.Done()
+
+		if e := recover(); e != nil {
+			r.observePanic(e)
+			r.Logger.Error("recovering from priority broadcasting mempool loop", "err", e, "stack", string(debug.Stack()))
+		}
+	}()
 
-		case <-r.closeCh:
-			// The reactor has signaled that we are stopped and thus we should
-			// implicitly exit this peer's goroutine.
+	for {
+		if !r.IsRunning() || ctx.Err() != nil {
 			return
 		}
+
+		memTx := r.mempool.NextGossipTxByPriority(peerMempoolID, func(tx *WrappedTx) bool {
+			// In announce mode, known also gates the hash-announce branch
+			// below; reusing it here means a push-mode delivery is likewise
+			// never repeated to this peer.
+			return known.Has(tx.hash)
+		})
+		if memTx == nil {
+			select {
+			case <-r.mempool.WaitForNextTx():
+				continue
+
+			case <-flushTimer.C:
+				flush()
+				continue
+
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if !r.shouldGossipToPeer(peerID, memTx.height) {
+			continue
+		}
+
+		announce := wantAnnounce && r.capabilities.SupportsAnnounce(peerID)
+		r.queueForPeer(ctx, batcher, known, announce, memTx, peerMempoolID)
+	}
+}
+
+// flushInterval returns the configured batch flush interval, falling back to
+// a small sane default if the node operator left it unset.
+func (r *Reactor) flushInterval() time.Duration {
+	if r.cfg.BatchFlushInterval > 0 {
+		return r.cfg.BatchFlushInterval
 	}
+	return defaultBatchFlushInterval
 }
\ No newline at end of file
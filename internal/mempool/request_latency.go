@@ -0,0 +1,55 @@
+package mempool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// requestLatencyTracker records when we last sent a peer a TxRequest, so the
+// round trip until its Txs reply arrives can be measured and fed to
+// PeerScorer.RecordLatency. The announce/request protocol is the only place
+// this reactor solicits a timed reply from a peer, so it's the natural
+// source for this signal.
+type requestLatencyTracker struct {
+	mtx    sync.Mutex
+	sentAt map[types.NodeID]time.Time
+}
+
+func newRequestLatencyTracker() *requestLatencyTracker {
+	return &requestLatencyTracker{sentAt: make(map[types.NodeID]time.Time)}
+}
+
+// Sent records that a TxRequest was just sent to peerID.
+func (t *requestLatencyTracker) Sent(peerID types.NodeID) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.sentAt[peerID] = time.Now()
+}
+
+// Received reports the latency since the last Sent call for peerID, if any
+// is still outstanding, clearing it either way so a given request is only
+// ever measured once.
+func (t *requestLatencyTracker) Received(peerID types.NodeID) (time.Duration, bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	sentAt, ok := t.sentAt[peerID]
+	if !ok {
+		return 0, false
+	}
+	delete(t.sentAt, peerID)
+
+	return time.Since(sentAt), true
+}
+
+// RemovePeer discards any pending request state held for peerID, e.g. on
+// disconnect.
+func (t *requestLatencyTracker) RemovePeer(peerID types.NodeID) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	delete(t.sentAt, peerID)
+}
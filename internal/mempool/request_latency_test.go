@@ -0,0 +1,52 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestRequestLatencyTrackerMeasuresRoundTrip(t *testing.T) {
+	tr := newRequestLatencyTracker()
+	peer := types.NodeID("peer1")
+
+	tr.Sent(peer)
+	time.Sleep(time.Millisecond)
+
+	d, ok := tr.Received(peer)
+	require.True(t, ok)
+	require.Greater(t, d, time.Duration(0))
+}
+
+func TestRequestLatencyTrackerNoPendingRequest(t *testing.T) {
+	tr := newRequestLatencyTracker()
+
+	_, ok := tr.Received(types.NodeID("peer1"))
+	require.False(t, ok)
+}
+
+func TestRequestLatencyTrackerReceivedIsOneShot(t *testing.T) {
+	tr := newRequestLatencyTracker()
+	peer := types.NodeID("peer1")
+
+	tr.Sent(peer)
+	_, ok := tr.Received(peer)
+	require.True(t, ok)
+
+	_, ok = tr.Received(peer)
+	require.False(t, ok)
+}
+
+func TestRequestLatencyTrackerRemovePeer(t *testing.T) {
+	tr := newRequestLatencyTracker()
+	peer := types.NodeID("peer1")
+
+	tr.Sent(peer)
+	tr.RemovePeer(peer)
+
+	_, ok := tr.Received(peer)
+	require.False(t, ok)
+}
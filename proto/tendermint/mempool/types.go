@@ -0,0 +1,175 @@
+package mempool
+
+// This file is a hand-written stand-in for types.pb.go, the file `make
+// proto-gen` would normally generate from types.proto in this directory.
+// No protoc toolchain is available to regenerate it here, so the types
+// below implement just enough of the generated surface (proto.Message,
+// p2p.Wrapper, and the accessors internal/mempool uses) to compile against;
+// running proto-gen for real should replace this file outright.
+
+import "fmt"
+
+// Message is the top-level message exchanged on the mempool p2p channel.
+type Message struct {
+	Sum isMessage_Sum
+}
+
+type isMessage_Sum interface {
+	isMessage_Sum()
+}
+
+type Message_Txs struct {
+	Txs *Txs
+}
+
+type Message_TxAnnounce struct {
+	TxAnnounce *TxAnnounce
+}
+
+type Message_TxRequest struct {
+	TxRequest *TxRequest
+}
+
+func (*Message_Txs) isMessage_Sum()        {}
+func (*Message_TxAnnounce) isMessage_Sum() {}
+func (*Message_TxRequest) isMessage_Sum()  {}
+
+func (m *Message) Reset()         { *m = Message{} }
+func (m *Message) String() string { return fmt.Sprintf("%+v", m.Sum) }
+func (*Message) ProtoMessage()    {}
+
+// Size returns an estimate of the envelope's wire size, used to size the
+// channel's receive buffer; it need not be exact.
+func (m *Message) Size() int {
+	if m == nil || m.Sum == nil {
+		return 0
+	}
+
+	switch sum := m.Sum.(type) {
+	case *Message_Txs:
+		return sum.Txs.Size()
+	case *Message_TxAnnounce:
+		return sum.TxAnnounce.Size()
+	case *Message_TxRequest:
+		return sum.TxRequest.Size()
+	default:
+		return 0
+	}
+}
+
+// Wrap implements p2p.Wrapper, allowing *Txs, *TxAnnounce, and *TxRequest
+// to be sent directly as a p2p.Envelope.Message and transparently wrapped
+// in a Message for the wire.
+func (m *Message) Wrap(inner interface{}) error {
+	switch msg := inner.(type) {
+	case *Txs:
+		m.Sum = &Message_Txs{Txs: msg}
+	case *TxAnnounce:
+		m.Sum = &Message_TxAnnounce{TxAnnounce: msg}
+	case *TxRequest:
+		m.Sum = &Message_TxRequest{TxRequest: msg}
+	default:
+		return fmt.Errorf("unknown message: %T", msg)
+	}
+	return nil
+}
+
+// Unwrap implements p2p.Wrapper, recovering the concrete message a Message
+// was constructed from.
+func (m *Message) Unwrap() (interface{}, error) {
+	switch sum := m.Sum.(type) {
+	case *Message_Txs:
+		return sum.Txs, nil
+	case *Message_TxAnnounce:
+		return sum.TxAnnounce, nil
+	case *Message_TxRequest:
+		return sum.TxRequest, nil
+	default:
+		return nil, fmt.Errorf("unknown message: %T", sum)
+	}
+}
+
+// Txs carries one or more full tx bodies, either pushed unsolicited or in
+// reply to a TxRequest.
+type Txs struct {
+	Txs [][]byte
+}
+
+func (m *Txs) Reset()         { *m = Txs{} }
+func (m *Txs) String() string { return fmt.Sprintf("%+v", m.Txs) }
+func (*Txs) ProtoMessage()    {}
+
+func (m *Txs) GetTxs() [][]byte {
+	if m == nil {
+		return nil
+	}
+	return m.Txs
+}
+
+func (m *Txs) Size() int {
+	if m == nil {
+		return 0
+	}
+	n := 0
+	for _, tx := range m.Txs {
+		n += len(tx)
+	}
+	return n
+}
+
+// TxAnnounce advertises the hashes of txs the sender has, without their
+// bodies, so the receiver can pull only the ones it doesn't already have
+// via TxRequest.
+type TxAnnounce struct {
+	Hashes [][]byte
+}
+
+func (m *TxAnnounce) Reset()         { *m = TxAnnounce{} }
+func (m *TxAnnounce) String() string { return fmt.Sprintf("%+v", m.Hashes) }
+func (*TxAnnounce) ProtoMessage()    {}
+
+func (m *TxAnnounce) GetHashes() [][]byte {
+	if m == nil {
+		return nil
+	}
+	return m.Hashes
+}
+
+func (m *TxAnnounce) Size() int {
+	if m == nil {
+		return 0
+	}
+	n := 0
+	for _, h := range m.Hashes {
+		n += len(h)
+	}
+	return n
+}
+
+// TxRequest asks the receiver of a TxAnnounce to send back the full bodies
+// for the listed hashes.
+type TxRequest struct {
+	Hashes [][]byte
+}
+
+func (m *TxRequest) Reset()         { *m = TxRequest{} }
+func (m *TxRequest) String() string { return fmt.Sprintf("%+v", m.Hashes) }
+func (*TxRequest) ProtoMessage()    {}
+
+func (m *TxRequest) GetHashes() [][]byte {
+	if m == nil {
+		return nil
+	}
+	return m.Hashes
+}
+
+func (m *TxRequest) Size() int {
+	if m == nil {
+		return 0
+	}
+	n := 0
+	for _, h := range m.Hashes {
+		n += len(h)
+	}
+	return n
+}